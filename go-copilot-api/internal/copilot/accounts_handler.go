@@ -0,0 +1,22 @@
+package copilot
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// AccountsHandler returns an http.HandlerFunc for the admin /accounts
+// endpoint, listing configured accounts and their token-expiry status
+// without leaking any oauth_token or exchanged API token.
+func AccountsHandler(tm *TokenManager) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"accounts": tm.Accounts(),
+		})
+	}
+}
@@ -14,26 +14,48 @@ import (
 
 // ModelsCache holds the cached models list and manages refresh.
 type ModelsCache struct {
-	mu           sync.RWMutex
-	modelsJSON   []byte
-	lastFetch    time.Time
-	ttl          time.Duration
-	tokenManager *TokenManager
-	httpClient   *http.Client
+	mu         sync.RWMutex
+	modelsJSON []byte
+	lastFetch  time.Time
+	ttl        time.Duration
+	httpClient *http.Client
+	cachePath  string
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
 }
 
-// NewModelsCache creates a new ModelsCache and fetches models on startup.
-// tokenManager is used to get the Copilot API token for authentication.
-// httpClient is optional - if nil, http.DefaultClient will be used.
-func NewModelsCache(ctx context.Context, tokenManager *TokenManager, ttl time.Duration, httpClient *http.Client) (*ModelsCache, error) {
-	if httpClient == nil {
-		httpClient = http.DefaultClient
+// NewModelsCache creates a new ModelsCache. httpClient is obtained from
+// tokenManager.Client, so its transport already attaches a valid Copilot
+// API token (and renews it) to every request - refresh no longer has to
+// fetch or inject a Bearer header itself.
+//
+// If cachePath already holds a fresh (within ttl) persisted copy, it's
+// loaded synchronously and the initial network fetch is skipped entirely.
+// If it holds a stale copy, that stale copy is served immediately and an
+// async refresh is kicked off in the background (stale-while-revalidate),
+// so callers never block cold start on an upstream round trip.
+func NewModelsCache(ctx context.Context, tokenManager *TokenManager, ttl time.Duration, cachePath string) (*ModelsCache, error) {
+	httpClient, err := tokenManager.Client("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Copilot HTTP client: %w", err)
 	}
 	cache := &ModelsCache{
-		ttl:          ttl,
-		tokenManager: tokenManager,
-		httpClient:   httpClient,
+		ttl:        ttl,
+		httpClient: httpClient,
+		cachePath:  cachePath,
+	}
+
+	if file, err := loadModelsCacheFile(cachePath); err == nil {
+		cache.modelsJSON = file.ModelsJSON
+		cache.lastFetch = file.LastFetch
+		if time.Since(file.LastFetch) <= ttl {
+			return cache, nil
+		}
+		go cache.refresh(context.Background())
+		return cache, nil
 	}
+
 	if err := cache.refresh(ctx); err != nil {
 		return nil, err
 	}
@@ -59,21 +81,19 @@ func (c *ModelsCache) GetModels(ctx context.Context) ([]byte, error) {
 	return nil, errors.New("models not available")
 }
 
-// refresh fetches the models list from the GitHub Copilot API.
+// refresh fetches the models list from the GitHub Copilot API. It always
+// issues a GET; the HTTP client's transport is responsible for attaching
+// and renewing the Copilot API token, for rate limiting, and for serving
+// cached/revalidated responses per Cache-Control and ETag, so refresh
+// itself no longer needs to duplicate any of that bookkeeping beyond the
+// local lastFetch marker used by GetModels.
 func (c *ModelsCache) refresh(ctx context.Context) error {
-	// Get fresh token from TokenManager
-	token, err := c.tokenManager.GetToken(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get Copilot token: %w", err)
-	}
-
 	const modelsURL = "https://api.githubcopilot.com/models"
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, modelsURL, nil)
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Editor-Version", "vscode/1.95.0")
 	req.Header.Set("Copilot-Integration-Id", "vscode-chat")
 
@@ -100,9 +120,52 @@ func (c *ModelsCache) refresh(ctx context.Context) error {
 	c.modelsJSON = data
 	c.lastFetch = time.Now()
 	c.mu.Unlock()
+
+	if err := saveModelsCacheFile(c.cachePath, data, c.lastFetch); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to persist models cache to %q: %v\n", c.cachePath, err)
+	}
 	return nil
 }
 
+// Start launches a background goroutine that refreshes the cache every
+// ttl/2, so GetModels never has to serve an expired-miss to a client after
+// startup. Call Stop to terminate it.
+func (c *ModelsCache) Start(ctx context.Context) {
+	c.stopCh = make(chan struct{})
+	interval := c.ttl / 2
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := c.refresh(ctx); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: background models refresh failed: %v\n", err)
+				}
+			case <-c.stopCh:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop terminates the background refresh goroutine started by Start. It is
+// safe to call multiple times, and safe to call even if Start was never
+// called.
+func (c *ModelsCache) Stop() {
+	c.stopOnce.Do(func() {
+		if c.stopCh != nil {
+			close(c.stopCh)
+		}
+	})
+}
+
 // SaveToFile writes the cached models JSON to a file (optional).
 func (c *ModelsCache) SaveToFile(path string) error {
 	c.mu.RLock()
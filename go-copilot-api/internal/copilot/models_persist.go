@@ -0,0 +1,84 @@
+package copilot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// modelsCacheFile is the on-disk representation of a ModelsCache, persisted
+// to Config.ModelsCachePath so it can be shared across proxy instances on
+// the same host and survive restarts.
+type modelsCacheFile struct {
+	ModelsJSON json.RawMessage `json:"models"`
+	LastFetch  time.Time       `json:"last_fetch"`
+}
+
+// loadModelsCacheFile reads and parses path. Callers should check
+// os.IsNotExist on the returned error to distinguish "never written" from a
+// real failure.
+func loadModelsCacheFile(path string) (*modelsCacheFile, error) {
+	if path == "" {
+		return nil, os.ErrNotExist
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f modelsCacheFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid models cache file %q: %w", path, err)
+	}
+	return &f, nil
+}
+
+// saveModelsCacheFile atomically persists modelsJSON/lastFetch to path. It
+// takes an exclusive flock on a sibling lock file for the duration of the
+// write so that multiple proxy instances on the same host never interleave
+// writes or observe a torn file, then renames a temp file into place.
+func saveModelsCacheFile(path string, modelsJSON []byte, lastFetch time.Time) error {
+	if path == "" {
+		return nil
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create cache dir %q: %w", dir, err)
+	}
+
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file for %q: %w", path, err)
+	}
+	defer lock.Close()
+	if err := lockFile(lock); err != nil {
+		return fmt.Errorf("failed to lock %q: %w", path, err)
+	}
+	defer unlockFile(lock)
+
+	data, err := json.Marshal(modelsCacheFile{ModelsJSON: modelsJSON, LastFetch: lastFetch})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".models-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp cache file in %q: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename temp cache file into place: %w", err)
+	}
+	return nil
+}
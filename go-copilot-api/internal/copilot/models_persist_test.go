@@ -0,0 +1,43 @@
+package copilot
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestModelsCacheFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.json")
+	lastFetch := time.Now().Truncate(time.Second)
+	want := json.RawMessage(`{"data":[{"id":"gpt-4"}]}`)
+
+	if err := saveModelsCacheFile(path, want, lastFetch); err != nil {
+		t.Fatalf("saveModelsCacheFile: %v", err)
+	}
+
+	got, err := loadModelsCacheFile(path)
+	if err != nil {
+		t.Fatalf("loadModelsCacheFile: %v", err)
+	}
+	if string(got.ModelsJSON) != string(want) {
+		t.Errorf("ModelsJSON = %s, want %s", got.ModelsJSON, want)
+	}
+	if !got.LastFetch.Equal(lastFetch) {
+		t.Errorf("LastFetch = %v, want %v", got.LastFetch, lastFetch)
+	}
+}
+
+func TestLoadModelsCacheFileMissing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "does-not-exist.json")
+	if _, err := loadModelsCacheFile(path); !os.IsNotExist(err) {
+		t.Fatalf("expected a not-exist error for a missing cache file, got %v", err)
+	}
+}
+
+func TestLoadModelsCacheFileEmptyPath(t *testing.T) {
+	if _, err := loadModelsCacheFile(""); !os.IsNotExist(err) {
+		t.Fatalf("expected a not-exist error for an empty path, got %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package copilot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/chlins/go-copilot-api/pkg/config"
+)
+
+// copilotTokenURL is GitHub's endpoint for exchanging a long-lived
+// oauth_token for a short-lived Copilot API token.
+const copilotTokenURL = "https://api.github.com/copilot_internal/v2/token"
+
+// tokenExchangeResponse is the response shape of copilotTokenURL.
+type tokenExchangeResponse struct {
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// copilotTokenSource is an oauth2.TokenSource that exchanges an account's
+// oauth_token for a Copilot API token. It has no caching of its own -
+// callers wrap it in oauth2.ReuseTokenSource to get that for free.
+type copilotTokenSource struct {
+	account    config.CopilotAccount
+	httpClient *http.Client
+}
+
+// Token performs the exchange and satisfies oauth2.TokenSource.
+func (s *copilotTokenSource) Token() (*oauth2.Token, error) {
+	req, err := http.NewRequest(http.MethodGet, copilotTokenURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "token "+s.account.OAuthToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange Copilot token for account %q: %w", s.account.User, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("token exchange for account %q failed: %s - %s", s.account.User, resp.Status, string(body))
+	}
+
+	var out tokenExchangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode token exchange response: %w", err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: out.Token,
+		TokenType:   "Bearer",
+		Expiry:      time.Unix(out.ExpiresAt, 0),
+	}, nil
+}
+
+// accountManager wraps a single account's copilotTokenSource in an
+// oauth2.ReuseTokenSource for automatic, concurrency-safe caching and
+// renewal, and exposes an http.Client that attaches the resulting token to
+// every request via oauth2.Transport.
+type accountManager struct {
+	account config.CopilotAccount
+	source  oauth2.TokenSource
+	client  *http.Client
+
+	mu        sync.Mutex
+	lastToken *oauth2.Token
+}
+
+func newAccountManager(account config.CopilotAccount, httpClient *http.Client) *accountManager {
+	source := oauth2.ReuseTokenSource(nil, &copilotTokenSource{account: account, httpClient: httpClient})
+	base := http.DefaultTransport
+	timeout := 30 * time.Second
+	if httpClient != nil {
+		if httpClient.Transport != nil {
+			base = httpClient.Transport
+		}
+		timeout = httpClient.Timeout
+	}
+	return &accountManager{
+		account: account,
+		source:  source,
+		client: &http.Client{
+			Transport: &oauth2.Transport{Source: source, Base: base},
+			Timeout:   timeout,
+		},
+	}
+}
+
+// getToken returns the current Copilot API token, exchanging or reusing it
+// per the wrapped oauth2.ReuseTokenSource's expiry bookkeeping.
+func (a *accountManager) getToken(ctx context.Context) (string, error) {
+	tok, err := a.source.Token()
+	if err != nil {
+		return "", err
+	}
+	a.mu.Lock()
+	a.lastToken = tok
+	a.mu.Unlock()
+	return tok.AccessToken, nil
+}
+
+// AccountStatus summarizes an account's current token state for the
+// /accounts admin endpoint, without leaking the oauth_token or the
+// exchanged API token. HasToken and TokenExpiry reflect the last token
+// returned through GetToken or GetTokenForAccount; tokens that the
+// transport of the Client obtains are not recorded here.
+type AccountStatus struct {
+	User        string    `json:"user,omitempty"`
+	GitHubAppId string    `json:"github_app_id,omitempty"`
+	HasToken    bool      `json:"has_token"`
+	TokenExpiry time.Time `json:"token_expiry,omitempty"`
+}
+
+func (a *accountManager) status() AccountStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	st := AccountStatus{User: a.account.User, GitHubAppId: a.account.GitHubAppId}
+	if a.lastToken != nil {
+		st.HasToken = a.lastToken.AccessToken != ""
+		st.TokenExpiry = a.lastToken.Expiry
+	}
+	return st
+}
+
+// TokenManager exchanges GitHub Copilot OAuth tokens for short-lived API
+// tokens across one or more configured accounts, holding one accountManager
+// per account and routing requests by sticky user, round-robin, random, or
+// failover strategy.
+type TokenManager struct {
+	managers []*accountManager
+	byUser   map[string]*accountManager
+	strategy string
+	stickyTo string
+
+	next uint64 // round-robin cursor
+}
+
+// NewTokenManager builds a TokenManager from cfg.CopilotAccounts and
+// returns an error if none are configured.
+func NewTokenManager(cfg *config.Config, httpClient *http.Client) (*TokenManager, error) {
+	if len(cfg.CopilotAccounts) == 0 {
+		return nil, fmt.Errorf("no Copilot accounts configured")
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	tm := &TokenManager{
+		byUser:   make(map[string]*accountManager),
+		strategy: cfg.AccountStrategy,
+		stickyTo: cfg.AccountUser,
+	}
+	for _, acct := range cfg.CopilotAccounts {
+		m := newAccountManager(acct, httpClient)
+		tm.managers = append(tm.managers, m)
+		if acct.User != "" {
+			tm.byUser[acct.User] = m
+		}
+	}
+	return tm, nil
+}
+
+// GetToken returns a Copilot API token, selecting the account per the
+// configured sticky user (COPILOT_ACCOUNT_USER) or rotation strategy.
+func (tm *TokenManager) GetToken(ctx context.Context) (string, error) {
+	return tm.GetTokenForAccount(ctx, tm.stickyTo)
+}
+
+// Client returns an *http.Client for the named account (or, if user is
+// empty, the sticky/default account) whose transport automatically
+// attaches a valid Copilot API token to every request via oauth2.Transport,
+// renewing it through the account's oauth2.ReuseTokenSource as needed.
+// Callers no longer need to call GetToken and set the Authorization header
+// themselves.
+func (tm *TokenManager) Client(user string) (*http.Client, error) {
+	if user == "" {
+		user = tm.stickyTo
+	}
+	if user != "" {
+		m, ok := tm.byUser[user]
+		if !ok {
+			return nil, fmt.Errorf("no Copilot account configured for user %q", user)
+		}
+		return m.client, nil
+	}
+	return tm.managers[0].client, nil
+}
+
+// GetTokenForAccount returns a Copilot API token for the named account,
+// e.g. as selected by the X-Copilot-Account request header. An empty user
+// defers to the configured rotation strategy across all accounts. With
+// strategy "failover", a 401/403 from the selected account automatically
+// falls through to the next configured account.
+func (tm *TokenManager) GetTokenForAccount(ctx context.Context, user string) (string, error) {
+	if user != "" {
+		m, ok := tm.byUser[user]
+		if !ok {
+			return "", fmt.Errorf("no Copilot account configured for user %q", user)
+		}
+		return m.getToken(ctx)
+	}
+
+	var lastErr error
+	for _, m := range tm.selectionOrder() {
+		token, err := m.getToken(ctx)
+		if err == nil {
+			return token, nil
+		}
+		lastErr = err
+		if tm.strategy != "failover" {
+			break
+		}
+	}
+	return "", fmt.Errorf("all Copilot accounts failed: %w", lastErr)
+}
+
+// selectionOrder returns the account managers to try, in order, for the
+// configured strategy. "failover" tries every account starting from the
+// round-robin cursor; "roundrobin" and "random" each try a single account.
+func (tm *TokenManager) selectionOrder() []*accountManager {
+	switch tm.strategy {
+	case "random":
+		return []*accountManager{tm.managers[rand.Intn(len(tm.managers))]}
+	case "failover":
+		idx := atomic.AddUint64(&tm.next, 1) - 1
+		n := uint64(len(tm.managers))
+		ordered := make([]*accountManager, 0, n)
+		for i := uint64(0); i < n; i++ {
+			ordered = append(ordered, tm.managers[(idx+i)%n])
+		}
+		return ordered
+	case "roundrobin":
+		idx := atomic.AddUint64(&tm.next, 1) - 1
+		return []*accountManager{tm.managers[idx%uint64(len(tm.managers))]}
+	default:
+		return tm.managers[:1]
+	}
+}
+
+// Accounts returns a status summary for every configured account, suitable
+// for the /accounts admin endpoint.
+func (tm *TokenManager) Accounts() []AccountStatus {
+	statuses := make([]AccountStatus, 0, len(tm.managers))
+	for _, m := range tm.managers {
+		statuses = append(statuses, m.status())
+	}
+	return statuses
+}
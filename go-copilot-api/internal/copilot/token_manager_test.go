@@ -0,0 +1,84 @@
+package copilot
+
+import (
+	"testing"
+
+	"github.com/chlins/go-copilot-api/pkg/config"
+)
+
+func newTestManagers(n int) []*accountManager {
+	managers := make([]*accountManager, n)
+	for i := range managers {
+		managers[i] = &accountManager{account: config.CopilotAccount{User: string(rune('a' + i))}}
+	}
+	return managers
+}
+
+func TestSelectionOrderDefaultStrategy(t *testing.T) {
+	tm := &TokenManager{managers: newTestManagers(3), strategy: ""}
+	got := tm.selectionOrder()
+	if len(got) != 1 || got[0] != tm.managers[0] {
+		t.Fatalf("default strategy should always return the first account, got %+v", got)
+	}
+}
+
+func TestSelectionOrderRoundRobin(t *testing.T) {
+	tm := &TokenManager{managers: newTestManagers(3), strategy: "roundrobin"}
+	var order []*accountManager
+	for i := 0; i < 6; i++ {
+		got := tm.selectionOrder()
+		if len(got) != 1 {
+			t.Fatalf("roundrobin should return exactly one account, got %d", len(got))
+		}
+		order = append(order, got[0])
+	}
+	for i, m := range order {
+		want := tm.managers[i%len(tm.managers)]
+		if m != want {
+			t.Errorf("call %d: got account %q, want %q", i, m.account.User, want.account.User)
+		}
+	}
+}
+
+func TestSelectionOrderRandom(t *testing.T) {
+	tm := &TokenManager{managers: newTestManagers(3), strategy: "random"}
+	for i := 0; i < 20; i++ {
+		got := tm.selectionOrder()
+		if len(got) != 1 {
+			t.Fatalf("random should return exactly one account, got %d", len(got))
+		}
+		found := false
+		for _, m := range tm.managers {
+			if got[0] == m {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("random returned an account not in tm.managers: %+v", got[0])
+		}
+	}
+}
+
+func TestSelectionOrderFailover(t *testing.T) {
+	tm := &TokenManager{managers: newTestManagers(3), strategy: "failover"}
+
+	first := tm.selectionOrder()
+	if len(first) != 3 {
+		t.Fatalf("failover should return every account, got %d", len(first))
+	}
+	if first[0] != tm.managers[0] {
+		t.Fatalf("first failover call should start at account 0, got %q", first[0].account.User)
+	}
+
+	second := tm.selectionOrder()
+	if second[0] != tm.managers[1] {
+		t.Fatalf("second failover call should start at account 1, got %q", second[0].account.User)
+	}
+	// The cursor wraps around rather than truncating the candidate list.
+	for i, m := range second {
+		want := tm.managers[(1+i)%len(tm.managers)]
+		if m != want {
+			t.Errorf("second call position %d: got %q, want %q", i, m.account.User, want.account.User)
+		}
+	}
+}
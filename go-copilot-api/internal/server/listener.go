@@ -0,0 +1,107 @@
+// Package server builds the set of net.Listeners the proxy should serve on.
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/chlins/go-copilot-api/pkg/config"
+)
+
+// Listeners returns the listeners the proxy should accept connections on,
+// based on cfg.ServerAddr and cfg.ListenSocket.
+//
+// If ListenSocket is set, a UNIX socket listener is added. The TCP listener
+// is added whenever ServerAddr is non-empty, so setting SERVER_ADDR="" while
+// ListenSocket is set yields a socket-only proxy; setting both yields a
+// proxy that serves on both at once.
+func Listeners(cfg *config.Config) ([]net.Listener, error) {
+	var listeners []net.Listener
+
+	if cfg.ListenSocket != "" {
+		l, err := listenSocket(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on unix socket %q: %w", cfg.ListenSocket, err)
+		}
+		listeners = append(listeners, l)
+	}
+
+	if cfg.ServerAddr != "" {
+		l, err := net.Listen("tcp", cfg.ServerAddr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %q: %w", cfg.ServerAddr, err)
+		}
+		listeners = append(listeners, l)
+	}
+
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("no listeners configured: set ServerAddr and/or ListenSocket")
+	}
+
+	return listeners, nil
+}
+
+// listenSocket binds a UNIX domain socket at cfg.ListenSocket, removing any
+// stale socket file left behind by a previous, uncleanly-terminated process,
+// and chmods it to cfg.ListenSocketMode. If cfg.SocketTLSCert/SocketTLSKey
+// are set, the listener is wrapped in TLS.
+func listenSocket(cfg *config.Config) (net.Listener, error) {
+	if err := removeStaleSocket(cfg.ListenSocket); err != nil {
+		return nil, err
+	}
+
+	l, err := net.Listen("unix", cfg.ListenSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, err := parseSocketMode(cfg.ListenSocketMode)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	if err := os.Chmod(cfg.ListenSocket, mode); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to chmod socket %q: %w", cfg.ListenSocket, err)
+	}
+
+	if cfg.SocketTLSCert != "" && cfg.SocketTLSKey != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.SocketTLSCert, cfg.SocketTLSKey)
+		if err != nil {
+			l.Close()
+			return nil, fmt.Errorf("failed to load socket TLS cert/key: %w", err)
+		}
+		l = tls.NewListener(l, &tls.Config{Certificates: []tls.Certificate{cert}})
+	}
+
+	return l, nil
+}
+
+// removeStaleSocket removes a pre-existing socket file so a restarted
+// process can rebind. It is a no-op if the path doesn't exist, and returns
+// an error without removing anything if the path exists but isn't a socket.
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("refusing to remove %q: not a socket file", path)
+	}
+	return os.Remove(path)
+}
+
+// parseSocketMode parses a mode string (e.g. "0660") into an os.FileMode.
+func parseSocketMode(mode string) (os.FileMode, error) {
+	v, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid socket mode %q: %w", mode, err)
+	}
+	return os.FileMode(v), nil
+}
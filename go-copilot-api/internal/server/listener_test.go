@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSocketMode(t *testing.T) {
+	cases := []struct {
+		mode    string
+		want    os.FileMode
+		wantErr bool
+	}{
+		{"0660", 0660, false},
+		{"0600", 0600, false},
+		{"777", 0777, false},
+		{"", 0, true},
+		{"0999", 0, true},
+		{"rw-rw----", 0, true},
+	}
+	for _, tc := range cases {
+		got, err := parseSocketMode(tc.mode)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseSocketMode(%q) = %v, want an error", tc.mode, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseSocketMode(%q) returned unexpected error: %v", tc.mode, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("parseSocketMode(%q) = %o, want %o", tc.mode, got, tc.want)
+		}
+	}
+}
+
+func TestRemoveStaleSocketNoExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "does-not-exist.sock")
+	if err := removeStaleSocket(path); err != nil {
+		t.Fatalf("removeStaleSocket on a missing path should be a no-op, got: %v", err)
+	}
+}
+
+func TestRemoveStaleSocketRefusesNonSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plainfile")
+	if err := os.WriteFile(path, []byte("not a socket"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := removeStaleSocket(path); err == nil {
+		t.Fatal("removeStaleSocket should refuse to remove a non-socket file")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("file should still exist after refusal, stat err: %v", err)
+	}
+}
+
+func TestRemoveStaleSocketRemovesSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stale.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("net.Listen: %v", err)
+	}
+	// Keep the socket file on disk after Close, as an uncleanly-terminated
+	// process would; by default Close unlinks it.
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	if err := removeStaleSocket(path); err != nil {
+		t.Fatalf("removeStaleSocket on a stale socket file: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected socket file to be removed, stat err: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -25,6 +26,34 @@ type Config struct {
 	DefaultModel       string       // Default model to use if not specified in request
 	HTTPProxy          string       // HTTP proxy URL (read from HTTP_PROXY, HTTPS_PROXY, or ALL_PROXY)
 	HTTPClient         *http.Client // Shared HTTP client with proxy support
+
+	ListenSocket     string // Path to a UNIX domain socket to listen on, in addition to (or instead of) TCP
+	ListenSocketMode string // File mode applied to the socket file (default: 0660)
+	SocketTLSCert    string // Optional PEM cert file to terminate TLS on the UNIX socket
+	SocketTLSKey     string // Optional PEM key file to terminate TLS on the UNIX socket
+
+	UpstreamRPS   float64 // Requests/sec allowed to the Copilot upstream API (0 disables rate limiting)
+	UpstreamBurst int     // Burst size for the upstream rate limiter
+
+	CopilotAccounts []CopilotAccount // All accounts parsed from apps.json (or a single env-provided account)
+	AccountUser     string           // COPILOT_ACCOUNT_USER: sticky selection by login
+	AccountStrategy string           // COPILOT_ACCOUNT_STRATEGY: roundrobin|random|failover, used when AccountUser is unset
+
+	ModelsCachePath string // Where the models cache is persisted across restarts (default: $XDG_CACHE_HOME/go-copilot-api/models.json)
+
+	CopilotCACertFile         string // Extra PEM CA bundle trusted for the upstream connection, e.g. a corporate MITM gateway
+	CopilotClientCertFile     string // Client certificate for mTLS to the upstream
+	CopilotClientKeyFile      string // Client private key for mTLS to the upstream
+	CopilotInsecureSkipVerify bool   // Disables upstream certificate verification entirely - logs a loud warning, never use in production
+	CopilotTLSMinVersion      string // Minimum TLS version for the upstream connection: "1.2" or "1.3" (default: "1.2")
+	CopilotServerName         string // SNI override for the upstream connection
+}
+
+// CopilotAccount is a single GitHub account entry parsed from apps.json.
+type CopilotAccount struct {
+	User        string
+	OAuthToken  string
+	GitHubAppId string
 }
 
 // Load reads configuration from environment variables, falling back to sensible defaults.
@@ -50,17 +79,36 @@ func Load() (*Config, error) {
 	}
 
 	cfg := &Config{
-		ServerAddr:         getEnv("SERVER_ADDR", ":8080"),
-		Debug:              getEnvBool("DEBUG", false),
-		CopilotToken:       getEnv("COPILOT_TOKEN", randomToken()),
-		ServerPort:         getEnv("COPILOT_SERVER_PORT", "9191"),
-		CORSAllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "*"),
-		DefaultModel:       getEnv("DEFAULT_MODEL", ""),
-		HTTPProxy:          httpProxy,
+		ServerAddr:                getEnv("SERVER_ADDR", ":8080"),
+		Debug:                     getEnvBool("DEBUG", false),
+		CopilotToken:              getEnv("COPILOT_TOKEN", randomToken()),
+		ServerPort:                getEnv("COPILOT_SERVER_PORT", "9191"),
+		CORSAllowedOrigins:        getEnv("CORS_ALLOWED_ORIGINS", "*"),
+		DefaultModel:              getEnv("DEFAULT_MODEL", ""),
+		HTTPProxy:                 httpProxy,
+		ListenSocket:              getEnv("COPILOT_LISTEN_SOCKET", ""),
+		ListenSocketMode:          getEnv("COPILOT_LISTEN_SOCKET_MODE", "0660"),
+		SocketTLSCert:             getEnv("COPILOT_LISTEN_SOCKET_TLS_CERT", ""),
+		SocketTLSKey:              getEnv("COPILOT_LISTEN_SOCKET_TLS_KEY", ""),
+		UpstreamRPS:               getEnvFloat("COPILOT_UPSTREAM_RPS", 0),
+		UpstreamBurst:             getEnvInt("COPILOT_UPSTREAM_BURST", 1),
+		AccountUser:               getEnv("COPILOT_ACCOUNT_USER", ""),
+		AccountStrategy:           getEnv("COPILOT_ACCOUNT_STRATEGY", ""),
+		ModelsCachePath:           getEnv("COPILOT_MODELS_CACHE_PATH", defaultModelsCachePath()),
+		CopilotCACertFile:         getEnv("COPILOT_CA_CERT_FILE", ""),
+		CopilotClientCertFile:     getEnv("COPILOT_CLIENT_CERT_FILE", ""),
+		CopilotClientKeyFile:      getEnv("COPILOT_CLIENT_KEY_FILE", ""),
+		CopilotInsecureSkipVerify: getEnvBool("COPILOT_INSECURE_SKIP_VERIFY", false),
+		CopilotTLSMinVersion:      getEnv("COPILOT_TLS_MIN_VERSION", "1.2"),
+		CopilotServerName:         getEnv("COPILOT_SERVER_NAME", ""),
+	}
+
+	if cfg.CopilotInsecureSkipVerify {
+		fmt.Fprintln(os.Stderr, "WARNING: COPILOT_INSECURE_SKIP_VERIFY is set - upstream certificate verification is disabled, this is insecure and should never be used in production")
 	}
 
 	// Create HTTP client with proxy support
-	httpClient, err := createHTTPClient(httpProxy)
+	httpClient, err := createHTTPClient(cfg, httpProxy, cfg.UpstreamRPS, cfg.UpstreamBurst)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP client with proxy: %w", err)
 	}
@@ -72,12 +120,17 @@ func Load() (*Config, error) {
 
 	// Try to get Copilot OAuth token from env first
 	token := getEnv("COPILOT_OAUTH_TOKEN", "")
-	if token == "" {
-		// Try to auto-detect from apps.json
-		token = findCopilotToken()
+	if token != "" {
+		cfg.CopilotAccounts = []CopilotAccount{{OAuthToken: token}}
+	} else {
+		// Auto-detect all accounts from apps.json (multiple are common when a
+		// user has both a personal and a work GitHub account signed into VS Code).
+		cfg.CopilotAccounts = findCopilotAccounts()
 	}
-	cfg.CopilotOAuthToken = token
 
+	if len(cfg.CopilotAccounts) > 0 {
+		cfg.CopilotOAuthToken = cfg.CopilotAccounts[0].OAuthToken
+	}
 	if cfg.CopilotOAuthToken == "" {
 		fmt.Fprintln(os.Stderr, "Warning: Copilot OAuth token not found in environment or apps.json")
 	}
@@ -107,6 +160,45 @@ func getEnvBool(key string, def bool) bool {
 	return b
 }
 
+// defaultModelsCachePath returns $XDG_CACHE_HOME/go-copilot-api/models.json
+// (os.UserCacheDir already honors XDG_CACHE_HOME on Unix, falling back to
+// $HOME/.cache), or "" if no cache directory could be determined.
+func defaultModelsCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "go-copilot-api", "models.json")
+}
+
+// getEnvFloat returns the float64 value of the environment variable if set, otherwise returns the default.
+func getEnvFloat(key string, def float64) float64 {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid float for %s: %v, using default %v\n", key, err, def)
+		return def
+	}
+	return f
+}
+
+// getEnvInt returns the int value of the environment variable if set, otherwise returns the default.
+func getEnvInt(key string, def int) int {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid int for %s: %v, using default %v\n", key, err, def)
+		return def
+	}
+	return i
+}
+
 // randomToken generates a random fallback token if COPILOT_TOKEN is not set.
 func randomToken() string {
 	b := make([]byte, 32)
@@ -118,9 +210,10 @@ func randomToken() string {
 	return fmt.Sprintf("%x", b)
 }
 
-// findCopilotToken attempts to locate and parse the Copilot OAuth token from the user's config directory.
-// Checks platform-specific locations for apps.json and returns the first oauth_token found.
-func findCopilotToken() string {
+// findCopilotAccounts attempts to locate and parse all accounts from the
+// user's GitHub Copilot apps.json, checking platform-specific locations.
+// Returns every entry with a non-empty oauth_token.
+func findCopilotAccounts() []CopilotAccount {
 	var configPath string
 	if runtime.GOOS == "windows" {
 		localAppData := os.Getenv("LOCALAPPDATA")
@@ -134,11 +227,11 @@ func findCopilotToken() string {
 		}
 	}
 	if configPath == "" {
-		return ""
+		return nil
 	}
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		return ""
+		return nil
 	}
 	var apps map[string]struct {
 		User        string `json:"user"`
@@ -146,22 +239,34 @@ func findCopilotToken() string {
 		GitHubAppId string `json:"githubAppId"`
 	}
 	if err := json.Unmarshal(data, &apps); err != nil {
-		return ""
+		return nil
 	}
+	var accounts []CopilotAccount
 	for _, v := range apps {
-		if v.OAuthToken != "" {
-			return v.OAuthToken
+		if v.OAuthToken == "" {
+			continue
 		}
+		accounts = append(accounts, CopilotAccount{
+			User:        v.User,
+			OAuthToken:  v.OAuthToken,
+			GitHubAppId: v.GitHubAppId,
+		})
 	}
-	return ""
+	return accounts
 }
 
-// createHTTPClient creates an HTTP client with optional proxy support.
-func createHTTPClient(proxyURL string) (*http.Client, error) {
+// createHTTPClient creates an HTTP client with optional proxy support. The
+// resulting client's Transport is wrapped with a token-bucket rate limiter
+// (rps/burst) and an in-memory response cache for idempotent GETs against
+// the Copilot upstream API; rps <= 0 disables rate limiting.
+func createHTTPClient(cfg *Config, proxyURL string, rps float64, burst int) (*http.Client, error) {
+	tlsConfig, err := buildTLSConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upstream TLS config: %w", err)
+	}
+
 	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			MinVersion: tls.VersionTLS12,
-		},
+		TLSClientConfig:     tlsConfig,
 		MaxIdleConns:        100,
 		MaxIdleConnsPerHost: 10,
 		IdleConnTimeout:     90 * time.Second,
@@ -176,11 +281,67 @@ func createHTTPClient(proxyURL string) (*http.Client, error) {
 	}
 
 	return &http.Client{
-		Transport: transport,
+		Transport: newUpstreamTransport(transport, rps, burst),
 		Timeout:   30 * time.Second,
 	}, nil
 }
 
+// buildTLSConfig builds the tls.Config used for the upstream Copilot
+// connection (and any proxied passthrough transports that reuse it),
+// honoring a custom CA bundle, client certificate for mTLS, minimum TLS
+// version, SNI override, and - loudly - a skip-verify escape hatch for
+// corporate MITM gateways.
+func buildTLSConfig(cfg *Config) (*tls.Config, error) {
+	minVersion, err := tlsVersion(cfg.CopilotTLSMinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         minVersion,
+		ServerName:         cfg.CopilotServerName,
+		InsecureSkipVerify: cfg.CopilotInsecureSkipVerify,
+	}
+
+	if cfg.CopilotCACertFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(cfg.CopilotCACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA cert file %q: %w", cfg.CopilotCACertFile, err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA cert file %q", cfg.CopilotCACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CopilotClientCertFile != "" || cfg.CopilotClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CopilotClientCertFile, cfg.CopilotClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client cert/key for mTLS: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// tlsVersion maps a COPILOT_TLS_MIN_VERSION value to its tls.VersionTLS*
+// constant. An empty value defaults to TLS 1.2.
+func tlsVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported COPILOT_TLS_MIN_VERSION %q: want \"1.2\" or \"1.3\"", version)
+	}
+}
+
 // GetHTTPClient returns the configured HTTP client with proxy support.
 // This should be used for all outgoing HTTP requests.
 func (c *Config) GetHTTPClient() *http.Client {
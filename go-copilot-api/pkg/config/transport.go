@@ -0,0 +1,224 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// cacheEntry is a stored (status, headers, body) tuple for a single cached
+// idempotent GET response, along with enough metadata to revalidate or
+// expire it.
+type cacheEntry struct {
+	status    int
+	header    http.Header
+	body      []byte
+	etag      string
+	expiresAt time.Time
+}
+
+func (e *cacheEntry) fresh() bool {
+	return time.Now().Before(e.expiresAt)
+}
+
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		StatusCode:    e.status,
+		Status:        http.StatusText(e.status),
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+	}
+}
+
+// CacheStats reports cumulative hit/miss counts for the upstream response cache.
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// copilotDataPlaneHost is the only host whose GETs get cached. In
+// particular this excludes copilotTokenURL (api.github.com), whose
+// token-exchange responses must never be served from cache - caching them
+// would mask a ReuseTokenSource renewal behind a stale, possibly-expired
+// token.
+const copilotDataPlaneHost = "api.githubcopilot.com"
+
+// upstreamTransport rate-limits and caches requests made against the
+// Copilot upstream API. It composes a token-bucket limiter with an
+// in-memory cache for idempotent GETs to copilotDataPlaneHost (notably
+// /models), and wraps a base http.RoundTripper (typically the
+// *http.Transport built by createHTTPClient).
+type upstreamTransport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+
+	mu    sync.Mutex
+	cache map[string]*cacheEntry
+
+	hits   uint64
+	misses uint64
+}
+
+// newUpstreamTransport wraps base with rate limiting and response caching.
+// rps <= 0 disables rate limiting entirely.
+func newUpstreamTransport(base http.RoundTripper, rps float64, burst int) http.RoundTripper {
+	t := &upstreamTransport{
+		base:  base,
+		cache: make(map[string]*cacheEntry),
+	}
+	if rps > 0 {
+		if burst < 1 {
+			burst = 1
+		}
+		t.limiter = rate.NewLimiter(rate.Limit(rps), burst)
+	}
+	return t
+}
+
+// RoundTrip waits for rate-limiter admission, serves fresh cached GETs
+// directly, revalidates expired entries with If-None-Match, and otherwise
+// delegates to the base transport.
+func (t *upstreamTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.limiter != nil {
+		if err := t.limiter.Wait(req.Context()); err != nil {
+			return nil, fmt.Errorf("upstream rate limiter: %w", err)
+		}
+	}
+
+	if req.Method != http.MethodGet || req.URL.Host != copilotDataPlaneHost {
+		return t.base.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	if entry := t.get(key); entry != nil {
+		if entry.fresh() {
+			atomic.AddUint64(&t.hits, 1)
+			return entry.response(req), nil
+		}
+		if entry.etag != "" {
+			req = req.Clone(req.Context())
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+	}
+
+	atomic.AddUint64(&t.misses, 1)
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		if entry := t.get(key); entry != nil {
+			resp.Body.Close()
+			return t.renew(key, entry, resp.Header, req), nil
+		}
+	}
+
+	t.store(key, resp)
+	return resp, nil
+}
+
+// Stats returns the cumulative hit/miss counters for the response cache.
+func (t *upstreamTransport) Stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&t.hits),
+		Misses: atomic.LoadUint64(&t.misses),
+	}
+}
+
+func (t *upstreamTransport) get(key string) *cacheEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cache[key]
+}
+
+// renew replaces a revalidated (304) entry with a fresh *cacheEntry carrying
+// a new expiresAt, rather than mutating the shared entry in place - entries
+// are read from other goroutines without holding t.mu, so in-place mutation
+// would race.
+func (t *upstreamTransport) renew(key string, entry *cacheEntry, header http.Header, req *http.Request) *http.Response {
+	renewed := &cacheEntry{
+		status:    entry.status,
+		header:    entry.header,
+		body:      entry.body,
+		etag:      entry.etag,
+		expiresAt: time.Now().Add(cacheTTL(header)),
+	}
+	t.mu.Lock()
+	t.cache[key] = renewed
+	t.mu.Unlock()
+	atomic.AddUint64(&t.hits, 1)
+	return renewed.response(req)
+}
+
+// store caches resp's (status, headers, body) if Cache-Control allows it,
+// replacing resp.Body with a fresh reader so the caller can still consume it.
+func (t *upstreamTransport) store(key string, resp *http.Response) {
+	ttl := cacheTTL(resp.Header)
+	if ttl <= 0 || resp.StatusCode != http.StatusOK {
+		return
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := &cacheEntry{
+		status:    resp.StatusCode,
+		header:    resp.Header.Clone(),
+		body:      body,
+		etag:      resp.Header.Get("ETag"),
+		expiresAt: time.Now().Add(ttl),
+	}
+	t.mu.Lock()
+	t.cache[key] = entry
+	t.mu.Unlock()
+}
+
+// cacheTTL derives a TTL from the response's Cache-Control max-age directive.
+// Responses without a positive max-age are treated as non-cacheable.
+func cacheTTL(header http.Header) time.Duration {
+	cc := header.Get("Cache-Control")
+	if cc == "" {
+		return 0
+	}
+	for _, directive := range strings.Split(cc, ",") {
+		directive = strings.TrimSpace(directive)
+		if strings.HasPrefix(directive, "no-store") || strings.HasPrefix(directive, "no-cache") {
+			return 0
+		}
+		if rest, ok := strings.CutPrefix(directive, "max-age="); ok {
+			secs, err := strconv.Atoi(rest)
+			if err != nil || secs <= 0 {
+				return 0
+			}
+			return time.Duration(secs) * time.Second
+		}
+	}
+	return 0
+}
+
+// cacheKey identifies a request for caching purposes: method, URL, and a
+// hash of the Authorization header so distinct callers never share cached
+// responses.
+func cacheKey(req *http.Request) string {
+	h := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return req.Method + " " + req.URL.String() + " " + hex.EncodeToString(h[:8])
+}
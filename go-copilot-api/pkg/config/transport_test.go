@@ -0,0 +1,129 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCacheTTL(t *testing.T) {
+	cases := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{"missing header", "", 0},
+		{"max-age", "max-age=30", 30 * time.Second},
+		{"max-age with other directives", "public, max-age=60", 60 * time.Second},
+		{"no-store", "no-store, max-age=60", 0},
+		{"no-cache", "no-cache", 0},
+		{"zero max-age", "max-age=0", 0},
+		{"negative max-age", "max-age=-5", 0},
+		{"invalid max-age", "max-age=soon", 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := http.Header{}
+			if tc.header != "" {
+				h.Set("Cache-Control", tc.header)
+			}
+			if got := cacheTTL(h); got != tc.want {
+				t.Errorf("cacheTTL(%q) = %v, want %v", tc.header, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCacheKeyDistinguishesAuthorization(t *testing.T) {
+	req1, _ := http.NewRequest(http.MethodGet, "https://api.githubcopilot.com/models", nil)
+	req1.Header.Set("Authorization", "token one")
+	req2, _ := http.NewRequest(http.MethodGet, "https://api.githubcopilot.com/models", nil)
+	req2.Header.Set("Authorization", "token two")
+
+	if cacheKey(req1) == cacheKey(req2) {
+		t.Fatal("cacheKey should differ for distinct Authorization headers")
+	}
+
+	req3, _ := http.NewRequest(http.MethodGet, "https://api.githubcopilot.com/models", nil)
+	req3.Header.Set("Authorization", "token one")
+	if cacheKey(req1) != cacheKey(req3) {
+		t.Fatal("cacheKey should be stable for identical requests")
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestUpstreamTransportCachesDataPlaneGETs(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	transport := newUpstreamTransport(http.DefaultTransport, 0, 0).(*upstreamTransport)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.URL.Host = copilotDataPlaneHost
+
+	client := &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		// Restore the real test-server host for the underlying dial while
+		// keeping the cache-relevant req.URL.Host as seen by RoundTrip.
+		out := r.Clone(r.Context())
+		out.URL.Host = srv.Listener.Addr().String()
+		out.URL.Scheme = "http"
+		return http.DefaultTransport.RoundTrip(out)
+	})}
+	transport.base = client.Transport
+
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("first RoundTrip: %v", err)
+	}
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("second RoundTrip: %v", err)
+	}
+	if hits != 1 {
+		t.Fatalf("expected 1 upstream hit for a cached data-plane GET, got %d", hits)
+	}
+	stats := transport.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestUpstreamTransportDoesNotCacheOtherHosts(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	transport := newUpstreamTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		out := r.Clone(r.Context())
+		out.URL.Host = srv.Listener.Addr().String()
+		out.URL.Scheme = "http"
+		return http.DefaultTransport.RoundTrip(out)
+	}), 0, 0).(*upstreamTransport)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.URL.Host = "api.github.com"
+
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("first RoundTrip: %v", err)
+	}
+	if _, err := transport.RoundTrip(req); err != nil {
+		t.Fatalf("second RoundTrip: %v", err)
+	}
+	if hits != 2 {
+		t.Fatalf("expected every GET to a non-data-plane host to bypass the cache, got %d hits", hits)
+	}
+}